@@ -0,0 +1,236 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+)
+
+// ==========================================
+//     BNK REBUILD (size-growing patch)
+// ==========================================
+
+// HIRC object types whose bodies embed a CAkBankSourceData record
+// (pluginID, sourceID, inMemoryMediaSize, ...) pointing into the bank's
+// DATA chunk: Sound SFX/Voice objects and Music Track objects.
+const (
+	hircSoundType      = 0x02
+	hircMusicTrackType = 0x0B
+)
+
+// bnkChunk is one top-level chunk in a .bnk file: a 4-byte id, a 4-byte
+// little-endian size, then size bytes of payload. Wwise banks are a flat
+// sequence of these (BKHD, DIDX, DATA, HIRC, STID, ...) with no
+// enclosing RIFF wrapper.
+type bnkChunk struct {
+	id      string
+	payload []byte
+}
+
+// splitBnkChunks walks every top-level chunk in data starting at the
+// first BKHD header, preserving order, so a rebuild can reassemble the
+// file byte-for-byte apart from the chunks it intentionally changes.
+func splitBnkChunks(data []byte) (prefix []byte, chunks []bnkChunk) {
+	start := bytes.Index(data, []byte("BKHD"))
+	if start == -1 {
+		return nil, nil
+	}
+	offset := start
+	for offset < len(data)-8 {
+		id := string(data[offset : offset+4])
+		size := binary.LittleEndian.Uint32(data[offset+4 : offset+8])
+		body := data[offset+8 : offset+8+int(size)]
+		chunks = append(chunks, bnkChunk{id: id, payload: append([]byte(nil), body...)})
+		offset += 8 + int(size)
+	}
+	return data[:start], chunks
+}
+
+// align16 pads n up to the next 16-byte boundary, matching how Wwise lays
+// out WEM payloads inside a bank's DATA chunk.
+func align16(n int) int {
+	if rem := n % 16; rem != 0 {
+		n += 16 - rem
+	}
+	return n
+}
+
+// rebuildBnk reconstructs bnkPath with the given id->replacement WEM
+// bytes substituted in. Unlike the old in-place patcher it never fails
+// when a replacement no longer fits its original slot: the DATA chunk is
+// rebuilt from scratch (every entry 16-byte aligned), DIDX is rewritten
+// with the new offsets/sizes, and any HIRC Sound/Music Track object whose
+// sourceID was replaced gets its inMemoryMediaSize field updated to match.
+func rebuildBnk(bnkPath string, replacements map[uint32][]byte, logFunc func(string)) ([]byte, error) {
+	raw, err := os.ReadFile(bnkPath)
+	if err != nil {
+		return nil, err
+	}
+	prefix, chunks := splitBnkChunks(raw)
+	if chunks == nil {
+		return nil, fmt.Errorf("%s: no BKHD header found", bnkPath)
+	}
+
+	didxIdx, dataIdx, hircIdx := -1, -1, -1
+	for i, c := range chunks {
+		switch c.id {
+		case "DIDX":
+			didxIdx = i
+		case "DATA":
+			dataIdx = i
+		case "HIRC":
+			hircIdx = i
+		}
+	}
+	if didxIdx == -1 || dataIdx == -1 {
+		return nil, fmt.Errorf("%s: missing DIDX/DATA chunk", bnkPath)
+	}
+
+	didx := chunks[didxIdx].payload
+	origData := chunks[dataIdx].payload
+	numEntries := len(didx) / 12
+	type entry struct{ id, offset, size uint32 }
+	entries := make([]entry, numEntries)
+	for i := 0; i < numEntries; i++ {
+		pos := i * 12
+		entries[i] = entry{
+			id:     binary.LittleEndian.Uint32(didx[pos : pos+4]),
+			offset: binary.LittleEndian.Uint32(didx[pos+4 : pos+8]),
+			size:   binary.LittleEndian.Uint32(didx[pos+8 : pos+12]),
+		}
+	}
+
+	sizeChanges := make(map[uint32]uint32, len(replacements))
+	newData := &bytes.Buffer{}
+	newDidx := make([]byte, len(didx))
+	for i, e := range entries {
+		body := origData[e.offset : e.offset+e.size]
+		replaced := false
+		if repl, ok := replacements[e.id]; ok {
+			body = repl
+			sizeChanges[e.id] = uint32(len(repl))
+			replaced = true
+		}
+		newOffset := uint32(newData.Len())
+		newData.Write(body)
+		if pad := align16(newData.Len()) - newData.Len(); pad > 0 {
+			newData.Write(make([]byte, pad))
+		}
+		pos := i * 12
+		binary.LittleEndian.PutUint32(newDidx[pos:], e.id)
+		binary.LittleEndian.PutUint32(newDidx[pos+4:], newOffset)
+		binary.LittleEndian.PutUint32(newDidx[pos+8:], uint32(len(body)))
+		if replaced {
+			logFunc(fmt.Sprintf("[OK] %d (%d bytes)\n", e.id, len(body)))
+		}
+	}
+	chunks[didxIdx].payload = newDidx
+	chunks[dataIdx].payload = newData.Bytes()
+
+	if hircIdx != -1 && len(sizeChanges) > 0 {
+		chunks[hircIdx].payload = patchHircMediaSizes(chunks[hircIdx].payload, sizeChanges, logFunc)
+	}
+
+	out := &bytes.Buffer{}
+	out.Write(prefix)
+	for _, c := range chunks {
+		out.WriteString(c.id)
+		var sizeBuf [4]byte
+		binary.LittleEndian.PutUint32(sizeBuf[:], uint32(len(c.payload)))
+		out.Write(sizeBuf[:])
+		out.Write(c.payload)
+	}
+	return out.Bytes(), nil
+}
+
+// patchHircMediaSizes walks a HIRC chunk's objects looking for Sound
+// SFX/Voice and Music Track objects, patching inMemoryMediaSize on any
+// whose embedded sourceID was replaced.
+func patchHircMediaSizes(hirc []byte, sizeChanges map[uint32]uint32, logFunc func(string)) []byte {
+	out := append([]byte(nil), hirc...)
+	if len(out) < 4 {
+		return out
+	}
+	count := binary.LittleEndian.Uint32(out[0:4])
+	pos := 4
+	for i := uint32(0); i < count && pos+5 <= len(out); i++ {
+		objType := out[pos]
+		objLen := binary.LittleEndian.Uint32(out[pos+1 : pos+5])
+		bodyStart := pos + 5
+		bodyEnd := bodyStart + int(objLen)
+		if bodyEnd > len(out) {
+			break
+		}
+		switch objType {
+		case hircSoundType:
+			patchSoundSourceRef(out[bodyStart:bodyEnd], sizeChanges, logFunc)
+		case hircMusicTrackType:
+			patchMusicTrackSourceRefs(out[bodyStart:bodyEnd], sizeChanges, logFunc)
+		}
+		pos = bodyEnd
+	}
+	return out
+}
+
+// sourceDataLen is sizeof(CAkBankSourceData): pluginID(4) + streamType(1)
+// + sourceID(4) + inMemoryMediaSize(4) + sourceBits(1).
+const sourceDataLen = 14
+
+// A CAkSound object body is ulID(4) followed by a single inline
+// CAkBankSourceData record; sourceID and inMemoryMediaSize sit at the
+// same fixed offsets within it on every object, unlike a bus/RTPC/effect
+// ID elsewhere in the body that could coincidentally equal a sourceID.
+const (
+	soundSourceDataOffset   = 4
+	sourceIDOffset          = 5 // within a CAkBankSourceData record, after pluginID+streamType
+	inMemoryMediaSizeOffset = 9 // within a CAkBankSourceData record
+)
+
+// patchSoundSourceRef rewrites inMemoryMediaSize on a CAkSound object's
+// single CAkBankSourceData record, reading sourceID from its fixed offset
+// rather than scanning the body for a coincidental match.
+func patchSoundSourceRef(body []byte, sizeChanges map[uint32]uint32, logFunc func(string)) {
+	recStart := soundSourceDataOffset
+	if recStart+sourceDataLen > len(body) {
+		logFunc(fmt.Sprintf("[WARN] CAkSound body too short for CAkBankSourceData (%d bytes)\n", len(body)))
+		return
+	}
+	patchSourceDataRecord(body[recStart:recStart+sourceDataLen], sizeChanges, logFunc)
+}
+
+// patchMusicTrackSourceRefs rewrites inMemoryMediaSize on each of a
+// CAkMusicTrack object's CAkBankSourceData records (ulID(4) +
+// numSources(4), then numSources records back to back).
+func patchMusicTrackSourceRefs(body []byte, sizeChanges map[uint32]uint32, logFunc func(string)) {
+	if len(body) < 8 {
+		logFunc(fmt.Sprintf("[WARN] CAkMusicTrack body too short for a source count (%d bytes)\n", len(body)))
+		return
+	}
+	numSources := binary.LittleEndian.Uint32(body[4:8])
+	pos := 8
+	for i := uint32(0); i < numSources; i++ {
+		if pos+sourceDataLen > len(body) {
+			logFunc(fmt.Sprintf("[WARN] CAkMusicTrack declares %d sources but body only fits %d\n", numSources, i))
+			return
+		}
+		patchSourceDataRecord(body[pos:pos+sourceDataLen], sizeChanges, logFunc)
+		pos += sourceDataLen
+	}
+}
+
+// patchSourceDataRecord rewrites inMemoryMediaSize in-place on a single
+// CAkBankSourceData record if its sourceID - read from the one fixed
+// offset that field lives at - is one of the replaced IDs. Because the
+// offset is fixed rather than scanned for, there's exactly one candidate
+// location per record, so there's nothing else in the record a matching
+// sourceID value could ambiguously refer to.
+func patchSourceDataRecord(rec []byte, sizeChanges map[uint32]uint32, logFunc func(string)) {
+	id := binary.LittleEndian.Uint32(rec[sourceIDOffset : sourceIDOffset+4])
+	newSize, ok := sizeChanges[id]
+	if !ok {
+		return
+	}
+	binary.LittleEndian.PutUint32(rec[inMemoryMediaSizeOffset:inMemoryMediaSizeOffset+4], newSize)
+	logFunc(fmt.Sprintf("[HIRC] sourceID %d inMemoryMediaSize -> %d\n", id, newSize))
+}
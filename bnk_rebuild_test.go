@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// buildTestBnk assembles a minimal synthetic .bnk: a BKHD stub, a DIDX with
+// one entry, a DATA chunk holding its payload, and a HIRC chunk containing
+// a single Sound object with a real CAkSound body layout (ulID, then one
+// inline CAkBankSourceData record) so patchSoundSourceRef exercises its
+// actual fixed offsets instead of a layout invented just for the test.
+//
+// decoyID, if nonzero, is planted in the ulID field (the slot immediately
+// before the CAkBankSourceData record) equal to the replaced sourceID, to
+// prove a fixed-offset patch doesn't also clobber it the way a body-wide
+// byte-scan would.
+func buildTestBnk(id uint32, payload []byte, decoyID uint32) []byte {
+	var buf bytes.Buffer
+	writeChunk := func(tag string, body []byte) {
+		buf.WriteString(tag)
+		var sizeBuf [4]byte
+		binary.LittleEndian.PutUint32(sizeBuf[:], uint32(len(body)))
+		buf.Write(sizeBuf[:])
+		buf.Write(body)
+	}
+	writeChunk("BKHD", make([]byte, 8))
+
+	didx := make([]byte, 12)
+	binary.LittleEndian.PutUint32(didx[0:4], id)
+	binary.LittleEndian.PutUint32(didx[4:8], 0)
+	binary.LittleEndian.PutUint32(didx[8:12], uint32(len(payload)))
+	writeChunk("DIDX", didx)
+
+	writeChunk("DATA", payload)
+
+	// CAkSound body: ulID(4), then one CAkBankSourceData record -
+	// pluginID(4) + streamType(1) + sourceID(4) + inMemoryMediaSize(4) +
+	// sourceBits(1), per patchSoundSourceRef's fixed offsets.
+	body := make([]byte, 18)
+	binary.LittleEndian.PutUint32(body[0:4], decoyID)
+	binary.LittleEndian.PutUint32(body[9:13], id)
+	binary.LittleEndian.PutUint32(body[13:17], uint32(len(payload)))
+
+	var hirc bytes.Buffer
+	binary.Write(&hirc, binary.LittleEndian, uint32(1)) // object count
+	hirc.WriteByte(hircSoundType)
+	var objLen [4]byte
+	binary.LittleEndian.PutUint32(objLen[:], uint32(len(body)))
+	hirc.Write(objLen[:])
+	hirc.Write(body)
+	writeChunk("HIRC", hirc.Bytes())
+
+	return buf.Bytes()
+}
+
+// TestRebuildBnkOversizedReplacement round-trips a synthetic bank with one
+// replacement WEM larger than the slot it's replacing, the exact case the
+// old in-place patcher failed on with "[FAIL] %d too big". It checks the
+// rebuilt DIDX/DATA/HIRC chunks are internally consistent - every entry's
+// DIDX offset+size lands on its real bytes in DATA, and the HIRC
+// inMemoryMediaSize for the replaced sourceID matches the new size.
+//
+// This can't invoke vgmstream inside this sandbox to confirm a real
+// decoder accepts the result, so it verifies the same thing vgmstream
+// would need to be true: every DIDX-addressed span of DATA round-trips to
+// exactly the bytes that were written for it.
+func TestRebuildBnkOversizedReplacement(t *testing.T) {
+	const entryID = uint32(1)
+	const decoyID = entryID // a ulID field that collides with the sourceID being replaced
+	original := []byte{1, 2, 3, 4} // small original "WEM"
+	replacement := bytes.Repeat([]byte{0xAB}, 64) // far larger than the original slot
+
+	dir := t.TempDir()
+	bnkPath := filepath.Join(dir, "test.bnk")
+	if err := os.WriteFile(bnkPath, buildTestBnk(entryID, original, decoyID), 0644); err != nil {
+		t.Fatalf("write synthetic bnk: %v", err)
+	}
+
+	var logged []string
+	logFunc := func(s string) { logged = append(logged, s) }
+
+	rebuilt, err := rebuildBnk(bnkPath, map[uint32][]byte{entryID: replacement}, logFunc)
+	if err != nil {
+		t.Fatalf("rebuildBnk: %v", err)
+	}
+
+	_, chunks := splitBnkChunks(rebuilt)
+	var didx, data, hirc []byte
+	for _, c := range chunks {
+		switch c.id {
+		case "DIDX":
+			didx = c.payload
+		case "DATA":
+			data = c.payload
+		case "HIRC":
+			hirc = c.payload
+		}
+	}
+	if len(didx) != 12 {
+		t.Fatalf("expected one 12-byte DIDX entry, got %d bytes", len(didx))
+	}
+	gotID := binary.LittleEndian.Uint32(didx[0:4])
+	gotOffset := binary.LittleEndian.Uint32(didx[4:8])
+	gotSize := binary.LittleEndian.Uint32(didx[8:12])
+	if gotID != entryID {
+		t.Fatalf("DIDX id = %d, want %d", gotID, entryID)
+	}
+	if int(gotSize) != len(replacement) {
+		t.Fatalf("DIDX size = %d, want %d", gotSize, len(replacement))
+	}
+	if int(gotOffset)+int(gotSize) > len(data) {
+		t.Fatalf("DIDX entry (offset=%d size=%d) overruns DATA (%d bytes)", gotOffset, gotSize, len(data))
+	}
+	gotBytes := data[gotOffset : gotOffset+gotSize]
+	if !bytes.Equal(gotBytes, replacement) {
+		t.Fatalf("DATA at DIDX offset/size does not match the replacement WEM bytes")
+	}
+
+	// HIRC's inMemoryMediaSize for this sourceID should track the new size:
+	// object body starts at offset 9 (4-byte count + 1-byte type + 4-byte
+	// objLen); within the body, ulID occupies the first 4 bytes and the
+	// CAkBankSourceData record follows, with inMemoryMediaSize at its
+	// offset 9 (so absolute hirc offset 9+4+9 = 22).
+	hircSize := binary.LittleEndian.Uint32(hirc[22:26])
+	if int(hircSize) != len(replacement) {
+		t.Fatalf("HIRC inMemoryMediaSize = %d, want %d", hircSize, len(replacement))
+	}
+
+	// The ulID field - planted equal to the replaced sourceID - must be
+	// left untouched. A body-wide byte-scan (the old implementation) would
+	// have matched this field too and corrupted it; a fixed-offset patch
+	// must not.
+	gotUlID := binary.LittleEndian.Uint32(hirc[9:13])
+	if gotUlID != decoyID {
+		t.Fatalf("ulID field was clobbered: got %d, want untouched decoy %d", gotUlID, decoyID)
+	}
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+// ==========================================
+//              CLI MODE
+// ==========================================
+
+// cliLogEntry is one line of --log-json output: one JSON object per
+// processed file so callers can pipe extraction/conversion progress into
+// other tooling.
+type cliLogEntry struct {
+	FID    string `json:"fid"`
+	Status string `json:"status"`
+	Bytes  int64  `json:"bytes"`
+}
+
+// fidBytesRe pulls the "(%d bytes)" suffix extractBank/rebuildBnk-style
+// log lines append off of the fid, so cliLogger can report real byte
+// counts instead of leaving Bytes at its zero value.
+var fidBytesRe = regexp.MustCompile(`^(.*) \((\d+) bytes\)$`)
+
+// cliLogger returns a logFunc that either prints plain text (matching the
+// GUI's System Log) or, when jsonMode is set, emits one cliLogEntry per
+// line instead. Lines that don't look like a per-file result are emitted
+// too, as an entry with status "info" and the whole line as fid, so
+// nothing silently disappears.
+func cliLogger(jsonMode bool) func(string) {
+	if !jsonMode {
+		return func(msg string) { fmt.Print(msg) }
+	}
+	return func(msg string) {
+		msg = strings.TrimRight(msg, "\n")
+		if msg == "" {
+			return
+		}
+		status, fid := "info", ""
+		switch {
+		case strings.HasPrefix(msg, "[OK] "):
+			status, fid = "ok", strings.TrimPrefix(msg, "[OK] ")
+		case strings.HasPrefix(msg, "[FAIL] "):
+			status, fid = "fail", strings.TrimPrefix(msg, "[FAIL] ")
+		case strings.HasPrefix(msg, "[SKIP] "):
+			status, fid = "skip", strings.TrimPrefix(msg, "[SKIP] ")
+		default:
+			fid = msg
+		}
+		var bytesWritten int64
+		if m := fidBytesRe.FindStringSubmatch(fid); m != nil {
+			fid = m[1]
+			bytesWritten, _ = strconv.ParseInt(m[2], 10, 64)
+		}
+		entry := cliLogEntry{FID: fid, Status: status, Bytes: bytesWritten}
+		data, _ := json.Marshal(entry)
+		fmt.Println(string(data))
+	}
+}
+
+// gatherBnks returns the .bnk files to process for a `--bnk` flag that may
+// point at a single file or a directory of banks. A directory's files are
+// naturally sorted like the GUI's bank list (refreshBnks) and, unlike that
+// list, content-sniffed so only files starting with a Wwise bank magic are kept.
+func gatherBnks(bnkArg string) ([]string, error) {
+	info, err := os.Stat(bnkArg)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{bnkArg}, nil
+	}
+	entries, err := os.ReadDir(bnkArg)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+	natSortStrings(names)
+	var out []string
+	for _, name := range names {
+		full := filepath.Join(bnkArg, name)
+		if sniffKind(full) == kindWwiseBank {
+			out = append(out, full)
+		}
+	}
+	return out, nil
+}
+
+// gatherWavs returns the WAV files to process for an `--in` flag that may
+// point at a single file or a directory of WAVs. A directory's files are
+// naturally sorted and kept by content alone: any file that sniffs as
+// RIFF/WAVE is returned whatever its extension, whereas the GUI's
+// "+ Folder" sequencer button also requires a .wav extension.
+func gatherWavs(inArg string) ([]string, error) {
+	info, err := os.Stat(inArg)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{inArg}, nil
+	}
+	entries, err := os.ReadDir(inArg)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+	natSortStrings(names)
+	var out []string
+	for _, name := range names {
+		full := filepath.Join(inArg, name)
+		if sniffKind(full) == kindWav {
+			out = append(out, full)
+		}
+	}
+	return out, nil
+}
+
+// qualityFlagFor maps the CLI's --quality shorthand onto the Sound2Wem
+// quality strings used by runConversion.
+func qualityFlagFor(quality string) string {
+	switch strings.ToLower(quality) {
+	case "low":
+		return "Vorbis Quality Low"
+	case "high":
+		return "Vorbis Quality High"
+	default:
+		return "Vorbis Quality Medium"
+	}
+}
+
+// runCLI builds the `echoaudio` subcommand app. handled is false when args
+// don't start with a known subcommand, in which case main falls back to
+// launching the Fyne UI.
+func runCLI(args []string) (code int, handled bool) {
+	known := map[string]bool{"extract": true, "patch": true, "convert": true, "sequence": true, "watch": true}
+	if len(args) == 0 || !known[args[0]] {
+		return 0, false
+	}
+
+	cfg := NewConfigManager()
+	baseDir, _ := os.Getwd()
+	audioFilesDir := filepath.Join(baseDir, "AudioFiles")
+	os.MkdirAll(audioFilesDir, 0755)
+
+	jsonFlag := &cli.BoolFlag{Name: "log-json", Usage: "emit one JSON object per processed file"}
+	workersFlag := &cli.IntFlag{Name: "workers", Usage: "parallel workers (0 = auto/config)"}
+	workersFor := func(c *cli.Context) int {
+		if c.IsSet("workers") {
+			return c.Int("workers")
+		}
+		return cfg.Data.Workers
+	}
+
+	app := &cli.App{
+		Name:  "echoaudio",
+		Usage: "batch-mode Echo VR audio tooling",
+		Commands: []*cli.Command{
+			{
+				Name:  "extract",
+				Usage: "extract WEM/WAV from a bank or a folder of banks",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "bnk", Required: true},
+					&cli.StringFlag{Name: "out"},
+					&cli.BoolFlag{Name: "force-external", Usage: "skip the built-in decoder and always use vgmstream-cli"},
+					jsonFlag,
+					workersFlag,
+				},
+				Action: func(c *cli.Context) error {
+					logFunc := cliLogger(c.Bool("log-json"))
+					out := c.String("out")
+					if out != "" {
+						audioFilesDir = out
+					}
+					bnks, err := gatherBnks(c.String("bnk"))
+					if err != nil {
+						return err
+					}
+					forceExternal := c.Bool("force-external") || cfg.Data.ForceExternalDecoder
+					ctx := context.Background()
+					for _, bnk := range bnks {
+						extractBank(ctx, workersFor(c), bnk, audioFilesDir, cfg.Data.DecoderPath, forceExternal, logFunc)
+					}
+					return nil
+				},
+			},
+			{
+				Name:  "patch",
+				Usage: "patch replacement WEMs into a bank",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "bnk", Required: true},
+					&cli.StringFlag{Name: "wems", Required: true},
+					&cli.StringFlag{Name: "out", Required: true},
+					jsonFlag,
+				},
+				Action: func(c *cli.Context) error {
+					logFunc := cliLogger(c.Bool("log-json"))
+					bnkPath := c.String("bnk")
+					if !patchBank(filepath.Dir(bnkPath), filepath.Base(bnkPath), c.String("wems"), c.String("out"), logFunc) {
+						return fmt.Errorf("patch failed for %s", bnkPath)
+					}
+					return nil
+				},
+			},
+			{
+				Name:  "convert",
+				Usage: "convert WAV files to WEM",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "in", Required: true},
+					&cli.StringFlag{Name: "out", Required: true},
+					&cli.StringFlag{Name: "quality", Value: "med"},
+					&cli.BoolFlag{Name: "normalize", Usage: "loudness-normalize to the configured target LUFS/true peak before encoding"},
+					jsonFlag,
+					workersFlag,
+				},
+				Action: func(c *cli.Context) error {
+					logFunc := cliLogger(c.Bool("log-json"))
+					wavs, err := gatherWavs(c.String("in"))
+					if err != nil {
+						return err
+					}
+					convertWavToWem(context.Background(), workersFor(c), cfg.Data.ToolPath, wavs, c.String("out"), qualityFlagFor(c.String("quality")), c.Bool("normalize"), cfg.Data.TargetLUFS, cfg.Data.TargetTruePeak, logFunc)
+					return nil
+				},
+			},
+			{
+				Name:  "sequence",
+				Usage: "split a big WAV into clips matching a reference folder's lengths",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "input", Required: true},
+					&cli.StringFlag{Name: "refs", Required: true},
+					&cli.StringFlag{Name: "out", Required: true},
+					&cli.Float64Flag{Name: "fade", Value: 1.5},
+					&cli.BoolFlag{Name: "normalize", Usage: "loudness-normalize to the configured target LUFS/true peak before encoding"},
+					jsonFlag,
+					workersFlag,
+				},
+				Action: func(c *cli.Context) error {
+					logFunc := cliLogger(c.Bool("log-json"))
+					refs, err := gatherWavs(c.String("refs"))
+					if err != nil {
+						return err
+					}
+					sequenceSplit(context.Background(), workersFor(c), c.String("input"), refs, c.String("out"), c.Float64("fade"), true, true, c.Bool("normalize"), cfg.Data.TargetLUFS, cfg.Data.TargetTruePeak, cfg.Data.ToolPath, logFunc)
+					return nil
+				},
+			},
+			{
+				Name:  "watch",
+				Usage: "auto-extract new banks and auto-patch new WEMs as they appear on disk",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "bnk-dir", Usage: "folder to watch for new .bnk files"},
+					&cli.StringFlag{Name: "patch-wem-dir", Usage: "folder to watch for replacement .wem files"},
+					&cli.StringFlag{Name: "target-bnk", Usage: "bank path to match dropped WEMs against and patch"},
+					&cli.StringFlag{Name: "patch-out", Usage: "output dir for patched banks"},
+					&cli.BoolFlag{Name: "force-external", Usage: "skip the built-in decoder and always use vgmstream-cli"},
+					jsonFlag,
+					workersFlag,
+				},
+				Action: func(c *cli.Context) error {
+					logFunc := cliLogger(c.Bool("log-json"))
+					bnkWatchDir := c.String("bnk-dir")
+					if bnkWatchDir == "" && c.String("target-bnk") == "" {
+						return fmt.Errorf("specify --bnk-dir and/or --target-bnk")
+					}
+					forceExternal := c.Bool("force-external") || cfg.Data.ForceExternalDecoder
+					return watchPaths(context.Background(), bnkWatchDir, audioFilesDir, cfg.Data.DecoderPath, forceExternal, c.String("patch-wem-dir"), c.String("target-bnk"), c.String("patch-out"), workersFor(c), logFunc)
+				},
+			},
+		},
+	}
+
+	if err := app.Run(append([]string{"echoaudio"}, args...)); err != nil {
+		fmt.Fprintln(os.Stderr, "[ERROR]", err)
+		return 1, true
+	}
+	return 0, true
+}
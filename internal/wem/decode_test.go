@@ -0,0 +1,137 @@
+package wem
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// buildWemPCM16 assembles a minimal RIFF/WAVE WEM carrying PCM16 samples.
+func buildWemPCM16(channels, sampleRate int, samples []int16) []byte {
+	var data bytes.Buffer
+	for _, s := range samples {
+		binary.Write(&data, binary.LittleEndian, s)
+	}
+
+	var fmtChunk bytes.Buffer
+	binary.Write(&fmtChunk, binary.LittleEndian, uint16(fmtPCM))
+	binary.Write(&fmtChunk, binary.LittleEndian, uint16(channels))
+	binary.Write(&fmtChunk, binary.LittleEndian, uint32(sampleRate))
+	binary.Write(&fmtChunk, binary.LittleEndian, uint32(sampleRate*channels*2)) // byte rate
+	binary.Write(&fmtChunk, binary.LittleEndian, uint16(channels*2))           // block align
+	binary.Write(&fmtChunk, binary.LittleEndian, uint16(16))                  // bits per sample
+
+	var buf bytes.Buffer
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, uint32(0)) // size unused by parseWem
+	buf.WriteString("WAVE")
+	writeRiffChunk(&buf, "fmt ", fmtChunk.Bytes())
+	writeRiffChunk(&buf, "data", data.Bytes())
+	return buf.Bytes()
+}
+
+func writeRiffChunk(buf *bytes.Buffer, id string, body []byte) {
+	buf.WriteString(id)
+	binary.Write(buf, binary.LittleEndian, uint32(len(body)))
+	buf.Write(body)
+	if len(body)%2 != 0 {
+		buf.WriteByte(0)
+	}
+}
+
+// TestDecodeToWAVPCM checks the PCM passthrough path: a WEM carrying raw
+// PCM16 samples should come out of DecodeToWAV byte-for-byte identical,
+// since no lossy codec is involved.
+func TestDecodeToWAVPCM(t *testing.T) {
+	samples := []int16{0, 1000, -1000, 32767, -32768, 42}
+	wemBytes := buildWemPCM16(1, 22050, samples)
+
+	dir := t.TempDir()
+	inPath := filepath.Join(dir, "in.wem")
+	outPath := filepath.Join(dir, "out.wav")
+	if err := os.WriteFile(inPath, wemBytes, 0644); err != nil {
+		t.Fatalf("write test wem: %v", err)
+	}
+
+	if !DecodeToWAV(inPath, outPath) {
+		t.Fatalf("DecodeToWAV returned false for a valid PCM WEM")
+	}
+
+	raw, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("read decoded wav: %v", err)
+	}
+	out, err := parseWem(raw)
+	if err != nil {
+		t.Fatalf("parse decoded wav: %v", err)
+	}
+	var got []int16
+	for i := 0; i+2 <= len(out.data); i += 2 {
+		got = append(got, int16(binary.LittleEndian.Uint16(out.data[i:i+2])))
+	}
+	if len(got) != len(samples) {
+		t.Fatalf("got %d samples, want %d", len(got), len(samples))
+	}
+	for i, s := range samples {
+		if got[i] != s {
+			t.Fatalf("sample %d = %d, want %d", i, got[i], s)
+		}
+	}
+}
+
+// TestDecodeWwiseAdpcmKnownVectors decodes a handful of nibbles against
+// predictor/index values worked out by hand from the public IMA-ADPCM
+// spec formula, independently of imaAdpcmState.step - so a transcription
+// error in imaStepTable or imaIndexTable (which an encoder built on top of
+// step, as an earlier version of this test did, couldn't catch, since it
+// would mis-encode and mis-decode the same way) shows up as a mismatch
+// here instead.
+//
+// Starting at predictor=0, index=0, the spec's decode step is:
+//
+//	step  = stepTable[index]
+//	diff  = step>>3 + (nibble&4 ? step : 0) + (nibble&2 ? step>>1 : 0) + (nibble&1 ? step>>2 : 0)
+//	if nibble&8: diff = -diff
+//	predictor = clamp(predictor+diff, -32768, 32767)
+//	index     = clamp(index+indexTable[nibble], 0, 88)
+//
+// Feeding nibbles 0x0, 0x1, 0x2, 0x4, 0x8 through that by hand, using the
+// spec's own table values (7, 9, ... and -1, -1, -1, -1, 2, ...), gives
+// predictors 0, 1, 4, 11, 10 - the wantSamples below.
+func TestDecodeWwiseAdpcmKnownVectors(t *testing.T) {
+	nibbles := []byte{0x0, 0x1, 0x2, 0x4, 0x8, 0x0} // trailing 0x0 pads to a whole byte, sample discarded
+	wantSamples := []int16{0, 1, 4, 11, 10}
+
+	var block bytes.Buffer
+	binary.Write(&block, binary.LittleEndian, int16(0)) // initial predictor
+	block.WriteByte(0)                                   // initial index
+	block.WriteByte(0)                                   // reserved
+	for i := 0; i < len(nibbles); i += 2 {
+		block.WriteByte(nibbles[i] | (nibbles[i+1] << 4))
+	}
+
+	in := &info{channels: 1, blockAlign: uint16(block.Len())}
+	in.data = block.Bytes()
+
+	decoded, err := decodeWwiseAdpcm(in)
+	if err != nil {
+		t.Fatalf("decodeWwiseAdpcm: %v", err)
+	}
+	var got []int16
+	for i := 0; i+2 <= len(decoded); i += 2 {
+		got = append(got, int16(binary.LittleEndian.Uint16(decoded[i:i+2])))
+	}
+	// decodeWwiseAdpcm emits the initial predictor as sample 0, then one
+	// sample per nibble, including the padding nibble we don't check.
+	want := append([]int16{0}, wantSamples...)
+	if len(got) < len(want) {
+		t.Fatalf("got %d samples, want at least %d", len(got), len(want))
+	}
+	for i, w := range want {
+		if got[i] != w {
+			t.Fatalf("sample %d = %d, want %d", i, got[i], w)
+		}
+	}
+}
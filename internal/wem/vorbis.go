@@ -0,0 +1,276 @@
+package wem
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jfreymuth/oggvorbis"
+)
+
+// ==========================================
+//     WWISE VORBIS RECONSTRUCTION
+// ==========================================
+//
+// Wwise strips the standard Ogg Vorbis header triad down to a compact
+// `vorb` chunk plus a packed setup packet, and frames audio packets with a
+// raw size prefix instead of Ogg's own lacing. To decode one without
+// vgmstream we have to: read the handful of fields vorb kept, rebuild a
+// standard identification/comment/setup packet triad, re-wrap the data
+// chunk's audio packets as an honest Ogg bitstream, and hand that to an
+// Ogg Vorbis decoder.
+//
+// The setup packet's codebooks are the one part Wwise can drop entirely
+// (when the game ships an external codebook library instead of an inline
+// one) - in that case all this package can do is look up a pre-extracted
+// table keyed by the setup packet's byte size. We don't bundle any such
+// table; drop "<size>.cbl" files into Settings/codebooks/ to enable that
+// path for a given title. Banks with inline setup packets decode without
+// needing one.
+//
+// KNOWN GAP: the standard Wwise codebook blobs are NOT embedded via
+// go:embed here, even though that was asked for. Audiokinetic's codebook
+// libraries are extracted from the proprietary Wwise SDK, not original
+// work of this project, and redistributing them - even compiled into this
+// binary - is a licensing question this package can't resolve unilaterally.
+// If Echo VR's own banks strip the setup packet down to a library
+// reference (common for shipped titles, to save space), this decoder
+// falls through to requiring vgmstream for those banks and only covers
+// banks with an inline setup packet. Flagging for the backlog owner to
+// decide whether that's an acceptable partial implementation, or to
+// supply a codebook table (and confirm redistribution rights) for
+// embedding.
+
+// codebookDir is where a user can drop pre-extracted Wwise codebook
+// library files (named "<setupPacketSize>.cbl") for titles that strip
+// their setup packet down to a library reference.
+const codebookDir = "Settings/codebooks"
+
+// vorbHeader is the subset of fields Wwise's `vorb` chunk carries that we
+// need to rebuild a standard Vorbis header triad. Layout matches the
+// common "modern" (post ~2013) 42-byte Wwise vorb chunk; older titles use
+// a different size and are left to the external decoder.
+type vorbHeader struct {
+	sampleCount        uint32
+	setupPacketOffset  uint32
+	firstAudioOffset   uint32
+	blocksizeExponents byte
+}
+
+func parseVorbHeader(vorb []byte) (*vorbHeader, error) {
+	if len(vorb) != 42 {
+		return nil, fmt.Errorf("vorb: unsupported chunk size %d", len(vorb))
+	}
+	return &vorbHeader{
+		sampleCount:        binary.LittleEndian.Uint32(vorb[0:4]),
+		setupPacketOffset:  binary.LittleEndian.Uint32(vorb[16:20]),
+		firstAudioOffset:   binary.LittleEndian.Uint32(vorb[20:24]),
+		blocksizeExponents: vorb[41],
+	}, nil
+}
+
+// lookupCodebookLibrary returns the pre-extracted setup packet matching
+// size bytes, if the user has dropped one into codebookDir.
+func lookupCodebookLibrary(size int) ([]byte, bool) {
+	path := filepath.Join(codebookDir, fmt.Sprintf("%d.cbl", size))
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// buildIdentPacket builds a standard Vorbis identification packet from
+// the WEM's fmt chunk plus the blocksize exponents vorb recorded.
+func buildIdentPacket(channels int, sampleRate uint32, blocksizeExponents byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte(0x01)
+	buf.WriteString("vorbis")
+	binary.Write(&buf, binary.LittleEndian, uint32(0)) // vorbis_version
+	buf.WriteByte(byte(channels))
+	binary.Write(&buf, binary.LittleEndian, sampleRate)
+	binary.Write(&buf, binary.LittleEndian, int32(0)) // bitrate_maximum
+	binary.Write(&buf, binary.LittleEndian, int32(0)) // bitrate_nominal
+	binary.Write(&buf, binary.LittleEndian, int32(0)) // bitrate_minimum
+	buf.WriteByte(blocksizeExponents)
+	buf.WriteByte(1) // framing flag
+	return buf.Bytes()
+}
+
+// buildCommentPacket builds a minimal, empty Vorbis comment packet.
+func buildCommentPacket() []byte {
+	var buf bytes.Buffer
+	buf.WriteByte(0x03)
+	buf.WriteString("vorbis")
+	vendor := "EchoVR-Audio-Editor"
+	binary.Write(&buf, binary.LittleEndian, uint32(len(vendor)))
+	buf.WriteString(vendor)
+	binary.Write(&buf, binary.LittleEndian, uint32(0)) // comment count
+	buf.WriteByte(1)                                    // framing flag
+	return buf.Bytes()
+}
+
+// readWwiseAudioPackets splits the audio portion of a Wwise vorbis data
+// chunk into individual packets, each prefixed by a 2-byte little-endian
+// size - the framing modern Wwise uses in place of Ogg's own lacing.
+func readWwiseAudioPackets(data []byte, offset int) [][]byte {
+	var packets [][]byte
+	for offset+2 <= len(data) {
+		size := int(binary.LittleEndian.Uint16(data[offset : offset+2]))
+		offset += 2
+		if offset+size > len(data) {
+			break
+		}
+		packets = append(packets, data[offset:offset+size])
+		offset += size
+	}
+	return packets
+}
+
+// decodeWwiseVorbis reconstructs a standard Ogg Vorbis stream from a WEM's
+// vorb/data chunks and decodes it to interleaved 16-bit PCM.
+func decodeWwiseVorbis(in *info) ([]byte, error) {
+	vorb, err := parseVorbHeader(in.vorb)
+	if err != nil {
+		return nil, err
+	}
+	if int(vorb.setupPacketOffset) >= len(in.data) || int(vorb.firstAudioOffset) > len(in.data) {
+		return nil, fmt.Errorf("vorbis: setup/audio offsets out of range")
+	}
+	setupSize := int(vorb.firstAudioOffset) - int(vorb.setupPacketOffset)
+	if setupSize <= 0 {
+		return nil, fmt.Errorf("vorbis: empty setup packet")
+	}
+	setupPacket := in.data[vorb.setupPacketOffset:vorb.firstAudioOffset]
+	if setupPacket[0] != 0x05 {
+		// Setup packet stripped down to a bare codebook reference; only
+		// decodable if the matching extracted library has been supplied.
+		lib, ok := lookupCodebookLibrary(setupSize)
+		if !ok {
+			return nil, fmt.Errorf("vorbis: no codebook library for setup size %d", setupSize)
+		}
+		setupPacket = lib
+	}
+
+	ident := buildIdentPacket(int(in.channels), in.sampleRate, vorb.blocksizeExponents)
+	comment := buildCommentPacket()
+	audioPackets := readWwiseAudioPackets(in.data, int(vorb.firstAudioOffset))
+	if len(audioPackets) == 0 {
+		return nil, fmt.Errorf("vorbis: no audio packets")
+	}
+
+	ogg := muxOggVorbis(ident, comment, setupPacket, audioPackets)
+	reader, err := oggvorbis.NewReader(bytes.NewReader(ogg))
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	buf := make([]float32, 4096)
+	for {
+		n, err := reader.Read(buf)
+		for i := 0; i < n; i++ {
+			sample := buf[i] * 32767
+			if sample > 32767 {
+				sample = 32767
+			}
+			if sample < -32768 {
+				sample = -32768
+			}
+			binary.Write(&out, binary.LittleEndian, int16(sample))
+		}
+		if err != nil {
+			break
+		}
+	}
+	return out.Bytes(), nil
+}
+
+// ==========================================
+//          MINIMAL OGG MUXER
+// ==========================================
+
+const oggCRCPoly = 0x04c11db7
+
+var oggCRCTable = func() [256]uint32 {
+	var t [256]uint32
+	for i := range t {
+		crc := uint32(i) << 24
+		for b := 0; b < 8; b++ {
+			if crc&0x80000000 != 0 {
+				crc = (crc << 1) ^ oggCRCPoly
+			} else {
+				crc <<= 1
+			}
+		}
+		t[i] = crc
+	}
+	return t
+}()
+
+// oggChecksum computes Ogg's page CRC: CRC-32 with polynomial 0x04c11db7,
+// unreflected, over the page with the checksum field itself zeroed. This
+// is hand-rolled rather than hash/crc32 because that package only
+// implements the reflected CRC-32 variant (IEEE/Castagnoli); Ogg's is not
+// bit-reflected, so crc32.MakeTable can't produce a matching table.
+func oggChecksum(page []byte) uint32 {
+	var crc uint32
+	for _, b := range page {
+		crc = (crc << 8) ^ oggCRCTable[byte(crc>>24)^b]
+	}
+	return crc
+}
+
+// oggPage serializes one Ogg page carrying packetData as its only
+// (possibly segmented) packet.
+func oggPage(packetData []byte, granulePos int64, pageSeq uint32, serial uint32, headerType byte) []byte {
+	var segments []byte
+	remaining := len(packetData)
+	for remaining >= 255 {
+		segments = append(segments, 255)
+		remaining -= 255
+	}
+	segments = append(segments, byte(remaining))
+
+	var buf bytes.Buffer
+	buf.WriteString("OggS")
+	buf.WriteByte(0) // stream structure version
+	buf.WriteByte(headerType)
+	binary.Write(&buf, binary.LittleEndian, granulePos)
+	binary.Write(&buf, binary.LittleEndian, serial)
+	binary.Write(&buf, binary.LittleEndian, pageSeq)
+	crcPos := buf.Len()
+	binary.Write(&buf, binary.LittleEndian, uint32(0)) // checksum placeholder
+	buf.WriteByte(byte(len(segments)))
+	buf.Write(segments)
+	buf.Write(packetData)
+
+	page := buf.Bytes()
+	crc := oggChecksum(page)
+	binary.LittleEndian.PutUint32(page[crcPos:crcPos+4], crc)
+	return page
+}
+
+// muxOggVorbis packages a reconstructed header triad plus the bank's
+// audio packets into a standard Ogg Vorbis bitstream, one packet per page
+// for simplicity (a higher packet density per page is a valid
+// optimization, not a correctness requirement).
+func muxOggVorbis(ident, comment, setup []byte, audioPackets [][]byte) []byte {
+	const serial = 0x1
+	var out bytes.Buffer
+	out.Write(oggPage(ident, 0, 0, serial, 0x02)) // beginning-of-stream
+	out.Write(oggPage(comment, 0, 1, serial, 0x00))
+	out.Write(oggPage(setup, 0, 2, serial, 0x00))
+	seq := uint32(3)
+	for i, pkt := range audioPackets {
+		headerType := byte(0x00)
+		if i == len(audioPackets)-1 {
+			headerType = 0x04 // end-of-stream
+		}
+		out.Write(oggPage(pkt, -1, seq, serial, headerType))
+		seq++
+	}
+	return out.Bytes()
+}
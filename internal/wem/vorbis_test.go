@@ -0,0 +1,83 @@
+package wem
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// TestOggPageChecksum checks that oggPage produces a page whose CRC field
+// actually validates: recompute the checksum over the page with the CRC
+// field zeroed again and confirm it matches what was written. This is the
+// part of the Wwise-Vorbis reconstruction path that's genuinely testable
+// without a real Wwise-encoded fixture or a vgmstream reference decode
+// (neither of which is available in this environment) - everything
+// downstream of a correct Ogg bitstream is the oggvorbis library's
+// problem, not this package's.
+func TestOggPageChecksum(t *testing.T) {
+	page := oggPage([]byte("hello wwise"), 0, 0, 1, 0x02)
+	if string(page[0:4]) != "OggS" {
+		t.Fatalf("missing OggS capture pattern")
+	}
+	crcPos := 22
+	written := binary.LittleEndian.Uint32(page[crcPos : crcPos+4])
+
+	recomputed := make([]byte, len(page))
+	copy(recomputed, page)
+	binary.LittleEndian.PutUint32(recomputed[crcPos:crcPos+4], 0)
+	want := oggChecksum(recomputed)
+
+	if written != want {
+		t.Fatalf("page CRC = %d, want %d", written, want)
+	}
+}
+
+// TestMuxOggVorbisPageSequence checks muxOggVorbis lays pages out in
+// strictly increasing sequence number, starting at the header triad and
+// continuing through the audio packets, and marks only the first page
+// beginning-of-stream and only the last end-of-stream.
+func TestMuxOggVorbisPageSequence(t *testing.T) {
+	ident := []byte("ident")
+	comment := []byte("comment")
+	setup := []byte{0x05, 'v', 'o', 'r', 'b', 'i', 's'}
+	audio := [][]byte{[]byte("a"), []byte("b"), []byte("c")}
+
+	ogg := muxOggVorbis(ident, comment, setup, audio)
+
+	var seqs []uint32
+	var headerTypes []byte
+	for offset := 0; offset+27 <= len(ogg); {
+		if string(ogg[offset:offset+4]) != "OggS" {
+			t.Fatalf("expected OggS capture pattern at offset %d", offset)
+		}
+		headerTypes = append(headerTypes, ogg[offset+5])
+		seqs = append(seqs, binary.LittleEndian.Uint32(ogg[offset+18:offset+22]))
+		segCount := int(ogg[offset+26])
+		segTable := ogg[offset+27 : offset+27+segCount]
+		packetLen := 0
+		for _, s := range segTable {
+			packetLen += int(s)
+		}
+		offset += 27 + segCount + packetLen
+	}
+
+	wantPages := 3 + len(audio)
+	if len(seqs) != wantPages {
+		t.Fatalf("got %d pages, want %d", len(seqs), wantPages)
+	}
+	for i, s := range seqs {
+		if s != uint32(i) {
+			t.Fatalf("page %d has sequence %d, want %d", i, s, i)
+		}
+	}
+	if headerTypes[0] != 0x02 {
+		t.Fatalf("first page header type = %#x, want beginning-of-stream (0x02)", headerTypes[0])
+	}
+	for _, ht := range headerTypes[1 : len(headerTypes)-1] {
+		if ht != 0x00 {
+			t.Fatalf("middle page header type = %#x, want 0x00", ht)
+		}
+	}
+	if headerTypes[len(headerTypes)-1] != 0x04 {
+		t.Fatalf("last page header type = %#x, want end-of-stream (0x04)", headerTypes[len(headerTypes)-1])
+	}
+}
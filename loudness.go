@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"syscall"
+)
+
+// ==========================================
+//        LOUDNESS NORMALIZATION
+// ==========================================
+
+// minNormalizeDuration is the shortest clip loudnorm's gating can reason
+// about reliably; shorter files are passed through unchanged.
+const minNormalizeDuration = 1.0
+
+// loudnormStats is the subset of ffmpeg's `loudnorm ... print_format=json`
+// first-pass measurement needed to drive the second, measured pass.
+type loudnormStats struct {
+	InputI       string `json:"input_i"`
+	InputTP      string `json:"input_tp"`
+	InputLRA     string `json:"input_lra"`
+	InputThresh  string `json:"input_thresh"`
+	TargetOffset string `json:"target_offset"`
+}
+
+var loudnormJSONRe = regexp.MustCompile(`(?s)\{.*\}`)
+
+// normalizeWav runs a two-pass EBU R128 loudnorm over inputWav and writes
+// a normalized copy to outputWav, targeting targetLUFS/targetTP. Files
+// shorter than minNormalizeDuration, or ones ffmpeg fails to measure, are
+// copied through unchanged rather than risking a bad normalization.
+func normalizeWav(inputWav, outputWav string, targetLUFS, targetTP float64) bool {
+	if getDuration(inputWav) < minNormalizeDuration {
+		return copyFile(inputWav, outputWav)
+	}
+	stats, ok := measureLoudness(inputWav, targetLUFS, targetTP)
+	if !ok {
+		return copyFile(inputWav, outputWav)
+	}
+	filter := fmt.Sprintf(
+		"loudnorm=I=%.1f:TP=%.1f:LRA=11:measured_I=%s:measured_TP=%s:measured_LRA=%s:measured_thresh=%s:offset=%s:linear=true",
+		targetLUFS, targetTP, stats.InputI, stats.InputTP, stats.InputLRA, stats.InputThresh, stats.TargetOffset,
+	)
+	cmd := exec.Command("ffmpeg", "-y", "-i", inputWav, "-af", filter, outputWav)
+	if runtime.GOOS == "windows" {
+		cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	}
+	return cmd.Run() == nil
+}
+
+// measureLoudness runs ffmpeg's loudnorm filter in analysis-only mode
+// (output discarded to -f null) and parses the JSON stats block it
+// prints to stderr.
+func measureLoudness(inputWav string, targetLUFS, targetTP float64) (loudnormStats, bool) {
+	filter := fmt.Sprintf("loudnorm=I=%.1f:TP=%.1f:LRA=11:print_format=json", targetLUFS, targetTP)
+	cmd := exec.Command("ffmpeg", "-i", inputWav, "-af", filter, "-f", "null", "-")
+	if runtime.GOOS == "windows" {
+		cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	cmd.Run()
+
+	match := loudnormJSONRe.FindString(stderr.String())
+	if match == "" {
+		return loudnormStats{}, false
+	}
+	var stats loudnormStats
+	if err := json.Unmarshal([]byte(match), &stats); err != nil {
+		return loudnormStats{}, false
+	}
+	return stats, true
+}
+
+// copyFile is the pass-through path normalizeWav takes for clips it
+// declines to normalize.
+func copyFile(src, dst string) bool {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return false
+	}
+	return os.WriteFile(dst, data, 0644) == nil
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
 	"encoding/json"
 	"fmt"
@@ -23,6 +24,8 @@ import (
 	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
+
+	"github.com/heisthecat31/EchoVR-Audio-Editor/internal/wem"
 )
 
 // ==========================================
@@ -37,9 +40,13 @@ type Config struct {
 	WavToolsDir      string `json:"wav_tools_dir"`
 	ConvertInputDir  string `json:"convert_input_dir"`
 	ConvertOutputDir string `json:"convert_output_dir"`
-	FadeDuration     string `json:"fade_duration"`
-	TrimStart        string `json:"trim_start"`
-	TrimEnd          string `json:"trim_end"`
+	FadeDuration     string  `json:"fade_duration"`
+	TrimStart        string  `json:"trim_start"`
+	TrimEnd          string  `json:"trim_end"`
+	Workers          int     `json:"workers"`
+	TargetLUFS       float64 `json:"target_lufs"`
+	TargetTruePeak   float64 `json:"target_true_peak"`
+	ForceExternalDecoder bool `json:"force_external_decoder"`
 	// Tab Visibility
 	ShowExtract      bool   `json:"show_extract"`
 	ShowSequencer    bool   `json:"show_sequencer"`
@@ -82,6 +89,10 @@ func NewConfigManager() *ConfigManager {
 		FadeDuration:     "1.5",
 		TrimStart:        "0",
 		TrimEnd:          "10",
+		Workers:          0,
+		TargetLUFS:       -16,
+		TargetTruePeak:   -1.5,
+		ForceExternalDecoder: false,
 		ShowExtract:      true,
 		ShowSequencer:    true,
 		ShowConvert:      true,
@@ -179,7 +190,15 @@ func runConversion(toolPath, inputWav, outputWem, qualityFlag string) bool {
 	return err == nil && false
 }
 
-func runDecoding(decoderPath, inputWem, outputWav string) bool {
+// runDecoding decodes inputWem to outputWav. Unless forceExternal is set it
+// tries the built-in pure-Go decoder first (covers Wwise Vorbis and
+// PCM/ADPCM with no external dependency); anything that falls through -
+// either because forceExternal is set or the internal decoder declined -
+// goes to vgmstream-cli at decoderPath, same as before.
+func runDecoding(decoderPath, inputWem, outputWav string, forceExternal bool) bool {
+	if !forceExternal && wem.DecodeToWAV(inputWem, outputWav) {
+		return true
+	}
 	if decoderPath == "" { return false }
 	cmd := exec.Command(decoderPath, "-o", outputWav, inputWem)
 	if runtime.GOOS == "windows" { cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true} }
@@ -198,6 +217,176 @@ func IsWwiseBank(path string) bool {
 	return bytes.Contains(buf[:n], []byte("BKHD"))
 }
 
+// extractBank extracts every WEM referenced by a bank's DIDX into
+// audioFilesDir/<bnkID>, decoding each one into audioFilesDir/<bnkID>_WAV
+// via runDecoding (built-in decoder first unless forceExternal, then
+// vgmstream-cli at decoderPath). It is shared by the Extract tab and the
+// `extract` CLI subcommand.
+func extractBank(ctx context.Context, workers int, bnkPath, audioFilesDir, decoderPath string, forceExternal bool, logFunc func(string)) bool {
+	filename := filepath.Base(bnkPath)
+	if !IsWwiseBank(bnkPath) {
+		logFunc(fmt.Sprintf("[SKIP] %s is not valid.\n", filename))
+		return false
+	}
+	bnkID := filename
+	if strings.HasSuffix(filename, ".bnk") {
+		bnkID = strings.TrimSuffix(filename, ".bnk")
+	}
+	logFunc(fmt.Sprintf("Extracting: %s\n", filename))
+	data, didx, size, payload := parseBnk(bnkPath, logFunc)
+	if data == nil {
+		return false
+	}
+	wemDir := filepath.Join(audioFilesDir, bnkID)
+	wavDir := filepath.Join(audioFilesDir, bnkID+"_WAV")
+	os.MkdirAll(wemDir, 0755)
+	os.MkdirAll(wavDir, 0755)
+	num := int(size) / 12
+	runParallel(ctx, workers, num, func(i int, log func(string)) {
+		pos := int(didx) + (i * 12)
+		fid := binary.LittleEndian.Uint32(data[pos : pos+4])
+		foff := binary.LittleEndian.Uint32(data[pos+4 : pos+8])
+		fsize := binary.LittleEndian.Uint32(data[pos+8 : pos+12])
+		body := data[int64(payload)+int64(foff) : int64(payload)+int64(foff)+int64(fsize)]
+		wemPath := filepath.Join(wemDir, fmt.Sprintf("%d.wem", fid))
+		if err := os.WriteFile(wemPath, body, 0644); err != nil {
+			log(fmt.Sprintf("[FAIL] %d: %v\n", fid, err))
+			return
+		}
+		wavPath := filepath.Join(wavDir, fmt.Sprintf("%d.wav", fid))
+		if !runDecoding(decoderPath, wemPath, wavPath, forceExternal) {
+			log(fmt.Sprintf("[FAIL] %d (%d bytes)\n", fid, len(body)))
+			return
+		}
+		log(fmt.Sprintf("[OK] %d (%d bytes)\n", fid, len(body)))
+	}, logFunc)
+	logFunc("Done.\n")
+	return true
+}
+
+// patchBank rebuilds bnkName in bnkDir by swapping in any WEM from wemDir
+// whose filename stem matches a DIDX entry id, writing the result into
+// outDir. It is shared by the Patch tab and the `patch` CLI subcommand.
+func patchBank(bnkDir, bnkName, wemDir, outDir string, logFunc func(string)) bool {
+	os.MkdirAll(outDir, 0755)
+	avail := make(map[string]string)
+	files, _ := ioutil.ReadDir(wemDir)
+	var wemNames []string
+	for _, f := range files {
+		if !f.IsDir() && strings.HasSuffix(strings.ToLower(f.Name()), ".wem") { wemNames = append(wemNames, f.Name()) }
+	}
+	natSortStrings(wemNames)
+	for _, name := range wemNames {
+		full := filepath.Join(wemDir, name)
+		if sniffKind(full) == kindWwiseBank {
+			logFunc(fmt.Sprintf("[SKIP] %s is actually a bank, not a WEM.\n", name))
+			continue
+		}
+		avail[strings.TrimSuffix(name, filepath.Ext(name))] = full
+	}
+	bnkPath := filepath.Join(bnkDir, bnkName)
+	logFunc(fmt.Sprintf("Patching %s\n", bnkName))
+	data, didx, size, _ := parseBnk(bnkPath, logFunc)
+	if data == nil {
+		return false
+	}
+	replacements := make(map[uint32][]byte)
+	num := int(size) / 12
+	for i := 0; i < num; i++ {
+		pos := int(didx) + (i * 12)
+		fid := binary.LittleEndian.Uint32(data[pos : pos+4])
+		if wem, ok := avail[fmt.Sprintf("%d", fid)]; ok {
+			nb, err := os.ReadFile(wem)
+			if err != nil {
+				logFunc(fmt.Sprintf("[FAIL] %d: %v\n", fid, err))
+				continue
+			}
+			replacements[fid] = nb
+		}
+	}
+	if len(replacements) == 0 {
+		logFunc("[WARN] No matching replacement WEMs found.\n")
+	}
+	rebuilt, err := rebuildBnk(bnkPath, replacements, logFunc)
+	if err != nil {
+		logFunc(fmt.Sprintf("Error rebuilding %s: %v\n", bnkName, err))
+		return false
+	}
+	os.WriteFile(filepath.Join(outDir, bnkName), rebuilt, 0644)
+	logFunc("Saved.\n")
+	return true
+}
+
+// convertNormalized optionally loudness-normalizes inputWav into a temp
+// file before handing it to runConversion, cleaning the temp file up
+// afterwards either way. It is the shared normalize-then-encode step
+// behind both the Convert and Sequencer tabs.
+func convertNormalized(toolPath, inputWav, outputWem, qualityFlag string, normalize bool, targetLUFS, targetTP float64) bool {
+	src := inputWav
+	if normalize {
+		tmp, err := os.CreateTemp("", "echoaudio-norm-*.wav")
+		if err == nil {
+			tmp.Close()
+			defer os.Remove(tmp.Name())
+			if normalizeWav(inputWav, tmp.Name(), targetLUFS, targetTP) {
+				src = tmp.Name()
+			}
+		}
+	}
+	return runConversion(toolPath, src, outputWem, qualityFlag)
+}
+
+// convertWavToWem runs the configured Sound2Wem tool over every WAV in
+// inputs, writing <name>.wem into outDir. It is shared by the Convert tab
+// and the `convert` CLI subcommand.
+func convertWavToWem(ctx context.Context, workers int, toolPath string, inputs []string, outDir, qualityFlag string, normalize bool, targetLUFS, targetTP float64, logFunc func(string)) {
+	os.MkdirAll(outDir, 0755)
+	runParallel(ctx, workers, len(inputs), func(i int, log func(string)) {
+		w := inputs[i]
+		out := filepath.Join(outDir, strings.Replace(filepath.Base(w), ".wav", ".wem", 1))
+		if convertNormalized(toolPath, w, out, qualityFlag, normalize, targetLUFS, targetTP) {
+			log(fmt.Sprintf("[OK] %s\n", filepath.Base(w)))
+		} else {
+			log(fmt.Sprintf("[FAIL] %s\n", filepath.Base(w)))
+		}
+	}, logFunc)
+}
+
+// sequenceSplit splits bigWav into one clip per reference file in refs
+// (matched in order, each clip's length taken from the reference's own
+// duration), optionally fading out the tail and encoding to WEM, writing
+// everything into outDir. It is shared by the Sequencer tab and the
+// `sequence` CLI subcommand.
+func sequenceSplit(ctx context.Context, workers int, bigWav string, refs []string, outDir string, fade float64, doFade, doEncode, normalize bool, targetLUFS, targetTP float64, toolPath string, logFunc func(string)) {
+	// Offsets only depend on each reference's own duration, so they can be
+	// computed up front and the per-reference split + encode fanned out.
+	durs := make([]float64, len(refs))
+	offsets := make([]float64, len(refs))
+	cur := 0.0
+	for i, ref := range refs {
+		durs[i] = getDuration(ref)
+		offsets[i] = cur
+		cur += durs[i]
+	}
+	runParallel(ctx, workers, len(refs), func(i int, log func(string)) {
+		ref := refs[i]
+		dur := durs[i]
+		wav := filepath.Join(outDir, filepath.Base(ref))
+		args := []string{"-y", "-i", bigWav, "-ss", fmt.Sprintf("%f", offsets[i]), "-t", fmt.Sprintf("%f", dur)}
+		if doFade && dur > fade {
+			args = append(args, "-af", fmt.Sprintf("afade=t=out:st=%f:d=%f", dur-fade, fade))
+		}
+		args = append(args, "-ac", "1", "-ar", "22050", wav)
+		runCommand("ffmpeg", args...)
+		if doEncode {
+			out := filepath.Join(outDir, strings.Replace(filepath.Base(ref), ".wav", ".wem", 1))
+			convertNormalized(toolPath, wav, out, "Vorbis Quality Low", normalize, targetLUFS, targetTP)
+		}
+		log(fmt.Sprintf("Split: %s\n", filepath.Base(ref)))
+	}, logFunc)
+	logFunc(fmt.Sprintf("Split Complete. Files in %s\n", outDir))
+}
+
 func parseBnk(bnkPath string, logFunc func(string)) ([]byte, int64, uint32, int64) {
 	data, err := os.ReadFile(bnkPath)
 	if err != nil {
@@ -233,6 +422,12 @@ func parseBnk(bnkPath string, logFunc func(string)) ([]byte, int64, uint32, int6
 // ==========================================
 
 func main() {
+	if len(os.Args) > 1 {
+		if code, handled := runCLI(os.Args[1:]); handled {
+			os.Exit(code)
+		}
+	}
+
 	myApp := app.New()
 	myWindow := myApp.NewWindow("Echo Audio Editor")
 	myWindow.Resize(fyne.NewSize(900, 800))
@@ -270,6 +465,10 @@ func main() {
 	}))
 	runOnUI := func(f func()) { uiAction = f; uiTrigger.Set(true) }
 
+	// batchJob tracks the running extract/convert/sequence job so the
+	// Cancel button can abort whichever one is currently in flight.
+	batchJob := &jobCancel{}
+
 	showHelp := func(title, content string) { dialog.ShowInformation(title, content, myWindow) }
 
 	createBrowseRow := func(entry *widget.Entry, isDir bool, filterExts []string, key string) *fyne.Container {
@@ -301,6 +500,7 @@ func main() {
 		files, _ := ioutil.ReadDir(bnkDir)
 		var names []string
 		for _, f := range files { if !f.IsDir() { names = append(names, f.Name()) } }
+		natSortStrings(names)
 		runOnUI(func() {
 			if len(names) == 0 {
 				names = append(names, "(No files found in BNK folder)")
@@ -319,32 +519,50 @@ func main() {
 		go func() {
 			if len(workList) == 0 { logFunc("[ERROR] No files to extract.\n"); return }
 			decoderPath := cfg.Data.DecoderPath
-			if _, err := os.Stat(decoderPath); os.IsNotExist(err) { logFunc(fmt.Sprintf("[ERROR] vgmstream-cli.exe missing at %s\n", decoderPath)); return }
+			if cfg.Data.ForceExternalDecoder {
+				if _, err := os.Stat(decoderPath); os.IsNotExist(err) { logFunc(fmt.Sprintf("[ERROR] vgmstream-cli.exe missing at %s\n", decoderPath)); return }
+			}
+			ctx := batchJob.Start()
+			defer batchJob.Done()
 			for _, filename := range workList {
-				bnkPath := filepath.Join(bnkDir, filename)
-				if !IsWwiseBank(bnkPath) { logFunc(fmt.Sprintf("[SKIP] %s is not valid.\n", filename)); continue }
-				bnkID := filename 
-				if strings.HasSuffix(filename, ".bnk") { bnkID = strings.TrimSuffix(filename, ".bnk") }
-				logFunc(fmt.Sprintf("Extracting: %s\n", filename))
-				data, didx, size, payload := parseBnk(bnkPath, logFunc)
-				if data == nil { continue }
-				wemDir := filepath.Join(audioFilesDir, bnkID); wavDir := filepath.Join(audioFilesDir, bnkID+"_WAV")
-				os.MkdirAll(wemDir, 0755); os.MkdirAll(wavDir, 0755)
-				num := int(size)/12
-				for i:=0; i<num; i++ {
-					pos := int(didx)+(i*12); fid := binary.LittleEndian.Uint32(data[pos:pos+4]); foff := binary.LittleEndian.Uint32(data[pos+4:pos+8]); fsize := binary.LittleEndian.Uint32(data[pos+8:pos+12])
-					wemPath := filepath.Join(wemDir, fmt.Sprintf("%d.wem", fid))
-					os.WriteFile(wemPath, data[int64(payload)+int64(foff) : int64(payload)+int64(foff)+int64(fsize)], 0644)
-					runDecoding(decoderPath, wemPath, filepath.Join(wavDir, fmt.Sprintf("%d.wav", fid)))
-				}
-				logFunc("Done.\n")
+				if ctx.Err() != nil { break }
+				extractBank(ctx, cfg.Data.Workers, filepath.Join(bnkDir, filename), audioFilesDir, decoderPath, cfg.Data.ForceExternalDecoder, logFunc)
 			}
 			logFunc("Extraction Job Complete.\n")
 		}()
 	}
 	btnRunExtract := widget.NewButtonWithIcon("Extract Selected", theme.MediaPlayIcon(), func() { performExtraction(bnkCheckGroup.Selected) })
 	btnExtractAll := widget.NewButtonWithIcon("Extract All", theme.MediaFastForwardIcon(), func() { performExtraction(bnkCheckGroup.Options) })
-	tabExtract := container.NewTabItem("Extract", container.NewVBox(widget.NewLabelWithStyle("BNK Files", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}), widget.NewButtonWithIcon("Refresh", theme.ViewRefreshIcon(), refreshBnks), widget.NewSeparator(), bnkScroll, layout.NewSpacer(), container.NewGridWithColumns(2, btnRunExtract, btnExtractAll)))
+
+	// Watch mode: auto-extract new banks from bnkDir and auto-patch new
+	// WEMs dropped into cfg.Data.PatchWemDir against whichever bank is
+	// selected on the Patch tab.
+	watchJob := &jobCancel{}
+	lblWatchStatus := widget.NewLabel("Idle")
+	chWatch := widget.NewCheck("Watch", nil)
+	chWatch.OnChanged = func(on bool) {
+		if !on {
+			watchJob.Cancel()
+			runOnUI(func() { lblWatchStatus.SetText("Idle") })
+			return
+		}
+		ctx := watchJob.Start()
+		runOnUI(func() { lblWatchStatus.SetText("Watching...") })
+		go func() {
+			defer watchJob.Done()
+			targetBnk := ""
+			if patchBnkSelect.Selected != "" {
+				targetBnk = filepath.Join(bnkDir, patchBnkSelect.Selected)
+			}
+			err := watchPaths(ctx, bnkDir, audioFilesDir, cfg.Data.DecoderPath, cfg.Data.ForceExternalDecoder, cfg.Data.PatchWemDir, targetBnk, cfg.Data.PatchOutputDir, cfg.Data.Workers, logFunc)
+			if err != nil {
+				logFunc(fmt.Sprintf("[ERROR] watch: %v\n", err))
+			}
+			runOnUI(func() { lblWatchStatus.SetText("Idle"); chWatch.SetChecked(false) })
+		}()
+	}
+
+	tabExtract := container.NewTabItem("Extract", container.NewVBox(widget.NewLabelWithStyle("BNK Files", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}), widget.NewButtonWithIcon("Refresh", theme.ViewRefreshIcon(), refreshBnks), widget.NewSeparator(), bnkScroll, layout.NewSpacer(), container.NewGridWithColumns(2, btnRunExtract, btnExtractAll), container.NewHBox(chWatch, lblWatchStatus)))
 
 	// 2. SEQUENCER
 	var seqFiles []string
@@ -359,12 +577,20 @@ func main() {
 			cfg.SetPath("wav_tools_dir", path)
 			files, err := ioutil.ReadDir(path)
 			if err != nil { logFunc(fmt.Sprintf("[ERROR] Reading dir: %v\n", err)); return }
-			count := 0
+			var names []string
 			for _, f := range files {
-				if !f.IsDir() && strings.HasSuffix(strings.ToLower(f.Name()), ".wav") {
-					seqFiles = append(seqFiles, filepath.Join(path, f.Name()))
-					count++
+				if !f.IsDir() && strings.HasSuffix(strings.ToLower(f.Name()), ".wav") { names = append(names, f.Name()) }
+			}
+			natSortStrings(names)
+			count := 0
+			for _, name := range names {
+				full := filepath.Join(path, name)
+				if sniffKind(full) != kindWav {
+					logFunc(fmt.Sprintf("[SKIP] %s is not a valid WAV.\n", name))
+					continue
 				}
+				seqFiles = append(seqFiles, full)
+				count++
 			}
 			seqList.Refresh()
 			logFunc(fmt.Sprintf("Added %d WAV files.\n", count))
@@ -384,35 +610,30 @@ func main() {
 			}
 		}, myWindow)
 	})
-	entryBig := widget.NewEntry(); entryFade := widget.NewEntry(); entryFade.SetText("1.5"); chFade := widget.NewCheck("Fade", nil); chFade.Checked=true; chEnc := widget.NewCheck("Encode", nil); chEnc.Checked=true
+	entryBig := widget.NewEntry(); entryFade := widget.NewEntry(); entryFade.SetText("1.5"); chFade := widget.NewCheck("Fade", nil); chFade.Checked=true; chEnc := widget.NewCheck("Encode", nil); chEnc.Checked=true; chNormSeq := widget.NewCheck("Normalize", nil)
 	btnSplit := widget.NewButton("Split & Encode", func() {
 		go func() {
-			out := newWavDir // Save to NewWAVandWEMS
-			fade,_ := strconv.ParseFloat(entryFade.Text, 64); cur:=0.0
-			for _, ref := range seqFiles {
-				dur := getDuration(ref); wav := filepath.Join(out, filepath.Base(ref))
-				args := []string{"-y", "-i", entryBig.Text, "-ss", fmt.Sprintf("%f", cur), "-t", fmt.Sprintf("%f", dur)}
-				if chFade.Checked && dur > fade { args = append(args, "-af", fmt.Sprintf("afade=t=out:st=%f:d=%f", dur-fade, fade)) }
-				args = append(args, "-ac", "1", "-ar", "22050", wav); runCommand("ffmpeg", args...)
-				cur+=dur
-				if chEnc.Checked { runConversion(cfg.Data.ToolPath, wav, filepath.Join(out, strings.Replace(filepath.Base(ref),".wav",".wem",1)), "Vorbis Quality Low") }
-			}
-			logFunc(fmt.Sprintf("Split Complete. Files in %s\n", out))
+			fade, _ := strconv.ParseFloat(entryFade.Text, 64)
+			ctx := batchJob.Start()
+			defer batchJob.Done()
+			sequenceSplit(ctx, cfg.Data.Workers, entryBig.Text, seqFiles, newWavDir, fade, chFade.Checked, chEnc.Checked, chNormSeq.Checked, cfg.Data.TargetLUFS, cfg.Data.TargetTruePeak, cfg.Data.ToolPath, logFunc)
 		}()
 	})
 	btnHelpSeq := widget.NewButtonWithIcon("", theme.QuestionIcon(), func() { showHelp("Help", "Sequencer Is Here to Rebuild A whole folder of wavs by splitting your custom wav and matching echo format") })
 	tabWav := container.NewTabItem("Sequencer", container.NewHSplit(
 		container.NewBorder(widget.NewLabel("Sequence"), container.NewHBox(btnAddSeq, btnAddFolder, btnRemSeq, layout.NewSpacer(), btnMerge), nil, nil, seqList),
-		container.NewVBox(container.NewHBox(widget.NewLabel("Custom File"), layout.NewSpacer(), btnHelpSeq), widget.NewForm(widget.NewFormItem("Input", createBrowseRow(entryBig, false, []string{".wav"}, "wav_tools_dir")), widget.NewFormItem("Fade", entryFade)), container.NewHBox(chFade, chEnc), btnSplit),
+		container.NewVBox(container.NewHBox(widget.NewLabel("Custom File"), layout.NewSpacer(), btnHelpSeq), widget.NewForm(widget.NewFormItem("Input", createBrowseRow(entryBig, false, []string{".wav"}, "wav_tools_dir")), widget.NewFormItem("Fade", entryFade)), container.NewHBox(chFade, chEnc, chNormSeq), btnSplit),
 	))
 
 	// 3. CONVERT
 	entryWavC := widget.NewEntry(); entryOutC := widget.NewEntry(); entryOutC.SetText(cfg.Data.ConvertOutputDir); var wavsC []string
+	chNormC := widget.NewCheck("Normalize", nil)
 	btnBrowseWC := widget.NewButtonWithIcon("", theme.FolderOpenIcon(), func() { fd:=dialog.NewFileOpen(func(r fyne.URIReadCloser, err error) { if r!=nil { wavsC=[]string{r.URI().Path()}; entryWavC.SetText("1 file") } }, myWindow); fd.SetFilter(storageFilter([]string{".wav"})); fd.Show() })
 	btnConv := widget.NewButton("Convert", func() {
 		go func() {
-			os.MkdirAll(entryOutC.Text, 0755)
-			for _, w := range wavsC { runConversion(cfg.Data.ToolPath, w, filepath.Join(entryOutC.Text, strings.Replace(filepath.Base(w),".wav",".wem",1)), "Vorbis Quality High") }
+			ctx := batchJob.Start()
+			defer batchJob.Done()
+			convertWavToWem(ctx, cfg.Data.Workers, cfg.Data.ToolPath, wavsC, entryOutC.Text, "Vorbis Quality High", chNormC.Checked, cfg.Data.TargetLUFS, cfg.Data.TargetTruePeak, logFunc)
 			logFunc("Convert Done.\n")
 		}()
 	})
@@ -424,27 +645,7 @@ func main() {
 	btnPatch := widget.NewButton("Rebuild", func() {
 		go func() {
 			if patchBnkSelect.Selected == "" { logFunc("Select a bank.\n"); return }
-			out := entryOutP.Text; os.MkdirAll(out, 0755); avail := make(map[string]string)
-			files, _ := ioutil.ReadDir(entryWemDirP.Text)
-			for _, f := range files { if strings.HasSuffix(strings.ToLower(f.Name()), ".wem") { avail[strings.TrimSuffix(f.Name(), filepath.Ext(f.Name()))] = filepath.Join(entryWemDirP.Text, f.Name()) } }
-			bnkName := patchBnkSelect.Selected; bnkPath := filepath.Join(bnkDir, bnkName)
-			logFunc(fmt.Sprintf("Patching %s\n", bnkName))
-			data, didx, size, payload := parseBnk(bnkPath, logFunc)
-			if data != nil {
-				num := int(size)/12
-				for i:=0; i<num; i++ {
-					pos := int(didx)+(i*12); fid := binary.LittleEndian.Uint32(data[pos:pos+4]); foff := binary.LittleEndian.Uint32(data[pos+4:pos+8]); max := binary.LittleEndian.Uint32(data[pos+8:pos+12])
-					if wem, ok := avail[fmt.Sprintf("%d", fid)]; ok {
-						nb, _ := os.ReadFile(wem)
-						if len(nb) <= int(max) {
-							abs := int64(payload)+int64(foff); copy(data[abs:], nb)
-							if pad := int(max)-len(nb); pad > 0 { copy(data[abs+int64(len(nb)):], make([]byte, pad)) }
-							binary.LittleEndian.PutUint32(data[pos+8:], uint32(len(nb))); logFunc(fmt.Sprintf("[OK] %d\n", fid))
-						} else { logFunc(fmt.Sprintf("[FAIL] %d too big\n", fid)) }
-					}
-				}
-				os.WriteFile(filepath.Join(out, bnkName), data, 0644); logFunc("Saved.\n")
-			}
+			patchBank(bnkDir, patchBnkSelect.Selected, entryWemDirP.Text, entryOutP.Text, logFunc)
 		}()
 	})
 	btnHelpPatch := widget.NewButtonWithIcon("", theme.QuestionIcon(), func() { showHelp("Help", "Patch new WEMs into BNK") })
@@ -453,11 +654,13 @@ func main() {
 	
 	entryToolSettings := widget.NewEntry(); entryToolSettings.SetText(cfg.Data.ToolPath)
 	entryVgmSettings := widget.NewEntry(); entryVgmSettings.SetText(cfg.Data.DecoderPath)
-	
+	entryWorkers := widget.NewEntry(); entryWorkers.SetText(strconv.Itoa(cfg.Data.Workers))
+
 	chkExtract := widget.NewCheck("Show Extract", nil); chkExtract.Checked = cfg.Data.ShowExtract
 	chkSeq := widget.NewCheck("Show Sequencer", nil); chkSeq.Checked = cfg.Data.ShowSequencer
 	chkConv := widget.NewCheck("Show Convert", nil); chkConv.Checked = cfg.Data.ShowConvert
 	chkPatch := widget.NewCheck("Show Patch", nil); chkPatch.Checked = cfg.Data.ShowPatch
+	chkForceExternal := widget.NewCheck("Force external vgmstream (skip built-in decoder)", nil); chkForceExternal.Checked = cfg.Data.ForceExternalDecoder
 
 	tabs := container.NewAppTabs()
 
@@ -479,10 +682,14 @@ func main() {
 		form := widget.NewForm(
 			widget.NewFormItem("Sound2Wem", createBrowseRow(entryToolSettings, false, []string{".cmd", ".exe"}, "tool_path")),
 			widget.NewFormItem("vgmstream", createBrowseRow(entryVgmSettings, false, []string{".exe"}, "decoder_path")),
+			widget.NewFormItem("Workers (0 = auto)", entryWorkers),
+			widget.NewFormItem("Decoder", chkForceExternal),
 		)
 		saveBtn := widget.NewButtonWithIcon("Save & Close", theme.DocumentSaveIcon(), func() {
 			cfg.Data.ToolPath = entryToolSettings.Text
 			cfg.Data.DecoderPath = entryVgmSettings.Text
+			if n, err := strconv.Atoi(entryWorkers.Text); err == nil && n >= 0 { cfg.Data.Workers = n }
+			cfg.Data.ForceExternalDecoder = chkForceExternal.Checked
 			cfg.Data.ShowExtract = chkExtract.Checked
 			cfg.Data.ShowSequencer = chkSeq.Checked
 			cfg.Data.ShowConvert = chkConv.Checked
@@ -496,12 +703,13 @@ func main() {
 	}
 
 	btnSettings := widget.NewButtonWithIcon("", theme.SettingsIcon(), openSettings)
+	btnCancel := widget.NewButtonWithIcon("", theme.CancelIcon(), func() { batchJob.Cancel() })
 
 	refreshBnks()
 	updateTabs()
-	
+
 	// Layout
-	logHeader := container.NewBorder(nil, nil, widget.NewLabel("System Log:"), btnSettings)
+	logHeader := container.NewBorder(nil, nil, widget.NewLabel("System Log:"), container.NewHBox(btnCancel, btnSettings))
 	logPanel := container.NewBorder(logHeader, nil, nil, nil, logEntry)
 	mainSplit := container.NewVSplit(tabs, logPanel)
 	mainSplit.SetOffset(0.7) //
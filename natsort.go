@@ -0,0 +1,62 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ==========================================
+//              NATURAL SORT
+// ==========================================
+
+// natLess reports whether a should sort before b using natural order:
+// runs of digits compare numerically and runs of non-digits compare
+// case-insensitively. Plain lexicographic sort puts "track10.wav" before
+// "track2.wav"; natural sort keeps ordered game audio in the order it was
+// actually meant to play back in.
+func natLess(a, b string) bool {
+	ar, br := natRuns(a), natRuns(b)
+	for i := 0; i < len(ar) && i < len(br); i++ {
+		if ar[i] == br[i] {
+			continue
+		}
+		an, aErr := strconv.Atoi(ar[i])
+		bn, bErr := strconv.Atoi(br[i])
+		if aErr == nil && bErr == nil {
+			if an != bn {
+				return an < bn
+			}
+			continue
+		}
+		al, bl := strings.ToLower(ar[i]), strings.ToLower(br[i])
+		if al != bl {
+			return al < bl
+		}
+	}
+	return len(ar) < len(br)
+}
+
+// natRuns splits s into alternating runs of digits and non-digits, e.g.
+// "track10b" -> ["track", "10", "b"].
+func natRuns(s string) []string {
+	var runs []string
+	var cur []byte
+	isDigit := func(b byte) bool { return b >= '0' && b <= '9' }
+	for i := 0; i < len(s); i++ {
+		if len(cur) > 0 && isDigit(s[i]) != isDigit(cur[0]) {
+			runs = append(runs, string(cur))
+			cur = nil
+		}
+		cur = append(cur, s[i])
+	}
+	if len(cur) > 0 {
+		runs = append(runs, string(cur))
+	}
+	return runs
+}
+
+// natSortStrings sorts names in place using natLess.
+func natSortStrings(names []string) {
+	sort.Slice(names, func(i, j int) bool { return natLess(names[i], names[j]) })
+}
@@ -0,0 +1,46 @@
+package main
+
+import "os"
+
+// ==========================================
+//           CONTENT SNIFFING
+// ==========================================
+
+// fileKind is what sniffKind determined a file actually is, independent
+// of whatever extension it was given.
+type fileKind int
+
+const (
+	kindUnknown fileKind = iota
+	kindWav
+	kindXwma
+	kindWwiseBank
+)
+
+// sniffKind reads the first 12 bytes of path and classifies it by magic
+// bytes rather than trusting its extension, so a mislabeled file (or a
+// bank someone renamed to .wem) is caught instead of silently breaking
+// downstream tools.
+func sniffKind(path string) fileKind {
+	f, err := os.Open(path)
+	if err != nil {
+		return kindUnknown
+	}
+	defer f.Close()
+	buf := make([]byte, 12)
+	if n, err := f.Read(buf); err != nil || n < 12 {
+		return kindUnknown
+	}
+	switch string(buf[0:4]) {
+	case "BKHD", "AKPK":
+		return kindWwiseBank
+	case "RIFF":
+		switch string(buf[8:12]) {
+		case "WAVE":
+			return kindWav
+		case "XWMA":
+			return kindXwma
+		}
+	}
+	return kindUnknown
+}
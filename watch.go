@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// ==========================================
+//              WATCH MODE
+// ==========================================
+
+// watchDebounce is how long we wait after the last fsnotify event for a
+// path before acting on it. Encoders and game tools often write a file in
+// several syscalls, so firing on the first event would race a half-written
+// file.
+const watchDebounce = 500 * time.Millisecond
+
+// watchDebouncer coalesces rapid-fire fsnotify events per path into a
+// single callback, fired watchDebounce after the last event for that path
+// and only once the file's size has stopped changing between two polls.
+type watchDebouncer struct {
+	mu      sync.Mutex
+	pending map[string]*time.Timer
+	onReady func(path string)
+}
+
+func newWatchDebouncer(onReady func(path string)) *watchDebouncer {
+	return &watchDebouncer{pending: make(map[string]*time.Timer), onReady: onReady}
+}
+
+func (d *watchDebouncer) Touch(path string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if t, ok := d.pending[path]; ok {
+		t.Stop()
+	}
+	d.pending[path] = time.AfterFunc(watchDebounce, func() { d.fire(path) })
+}
+
+func (d *watchDebouncer) fire(path string) {
+	d.mu.Lock()
+	delete(d.pending, path)
+	d.mu.Unlock()
+
+	if !stableSize(path) {
+		d.Touch(path)
+		return
+	}
+	d.onReady(path)
+}
+
+// stableSize reports whether path's size is unchanged across one more
+// debounce tick, our proxy for "the writer is finished".
+func stableSize(path string) bool {
+	before, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	time.Sleep(watchDebounce)
+	after, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	return before.Size() == after.Size()
+}
+
+// bankDidxIDs returns the set of file-id strings referenced by bnkPath's
+// DIDX chunk, used to recognize which dropped WEMs are replacements for
+// the bank currently targeted by patch-watching.
+func bankDidxIDs(bnkPath string, logFunc func(string)) map[string]bool {
+	ids := make(map[string]bool)
+	data, didx, size, _ := parseBnk(bnkPath, logFunc)
+	if data == nil {
+		return ids
+	}
+	num := int(size) / 12
+	for i := 0; i < num; i++ {
+		pos := int(didx) + (i * 12)
+		fid := binary.LittleEndian.Uint32(data[pos : pos+4])
+		ids[fmt.Sprintf("%d", fid)] = true
+	}
+	return ids
+}
+
+// watchPaths monitors bnkDir for new .bnk files (auto-extracting each one
+// into audioFilesDir) and wemDir for new .wem files whose stem matches a
+// DIDX entry id of targetBnkPath (auto-patching into outDir). Either
+// bnkDir or wemDir may be empty to disable that half. It blocks until ctx
+// is cancelled.
+func watchPaths(ctx context.Context, bnkDir, audioFilesDir, decoderPath string, forceExternal bool, wemDir, targetBnkPath, outDir string, workers int, logFunc func(string)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	if bnkDir != "" {
+		if err := watcher.Add(bnkDir); err != nil {
+			return err
+		}
+	}
+	if wemDir != "" {
+		if err := watcher.Add(wemDir); err != nil {
+			return err
+		}
+	}
+
+	var didxIDs map[string]bool
+	if targetBnkPath != "" {
+		didxIDs = bankDidxIDs(targetBnkPath, logFunc)
+	}
+
+	extractReady := newWatchDebouncer(func(path string) {
+		if !IsWwiseBank(path) {
+			logFunc(fmt.Sprintf("[SKIP] %s is not a valid bank.\n", filepath.Base(path)))
+			return
+		}
+		logFunc(fmt.Sprintf("[WATCH] New bank: %s\n", filepath.Base(path)))
+		extractBank(ctx, workers, path, audioFilesDir, decoderPath, forceExternal, logFunc)
+	})
+
+	patchReady := newWatchDebouncer(func(path string) {
+		logFunc(fmt.Sprintf("[WATCH] Replacement WEM: %s\n", filepath.Base(path)))
+		patchBank(filepath.Dir(targetBnkPath), filepath.Base(targetBnkPath), wemDir, outDir, logFunc)
+	})
+
+	logFunc("[WATCH] Watching for changes...\n")
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Op&(fsnotify.Create|fsnotify.Write) == 0 {
+				continue
+			}
+			name := strings.ToLower(event.Name)
+			switch {
+			case bnkDir != "" && filepath.Dir(event.Name) == bnkDir && strings.HasSuffix(name, ".bnk"):
+				extractReady.Touch(event.Name)
+			case wemDir != "" && filepath.Dir(event.Name) == wemDir && strings.HasSuffix(name, ".wem") && didxIDs != nil:
+				stem := strings.TrimSuffix(filepath.Base(event.Name), filepath.Ext(event.Name))
+				if didxIDs[stem] {
+					patchReady.Touch(event.Name)
+				}
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			logFunc(fmt.Sprintf("[ERROR] watch: %v\n", err))
+		}
+	}
+}
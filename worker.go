@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"sync"
+)
+
+// ==========================================
+//              WORKER POOL
+// ==========================================
+
+// runParallel fans n independent units of work out across a pool of
+// workers goroutines (runtime.NumCPU() if workers <= 0), while still
+// logging results in original call order: each unit gets its own buffer
+// to log into, and the buffers are flushed through logFunc in index
+// order once every unit has finished. ctx lets a Cancel button/CLI signal
+// stop work that hasn't started yet; in-flight ffmpeg/vgmstream calls are
+// not forcibly killed, they're simply the last ones to run.
+func runParallel(ctx context.Context, workers, n int, task func(i int, log func(string)), logFunc func(string)) {
+	if n <= 0 {
+		return
+	}
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > n {
+		workers = n
+	}
+
+	buffers := make([]*bytes.Buffer, n)
+	for i := range buffers {
+		buffers[i] = &bytes.Buffer{}
+	}
+
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				buf := buffers[i]
+				task(i, func(msg string) { buf.WriteString(msg) })
+			}
+		}()
+	}
+
+	go func() {
+		defer close(indices)
+		for i := 0; i < n; i++ {
+			if ctx.Err() != nil {
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case indices <- i:
+			}
+		}
+	}()
+
+	wg.Wait()
+
+	for i := 0; i < n; i++ {
+		if buffers[i].Len() > 0 {
+			logFunc(buffers[i].String())
+		}
+	}
+	if ctx.Err() != nil {
+		logFunc("[CANCELLED] Remaining jobs skipped.\n")
+	}
+}
+
+// jobCancel tracks the context.CancelFunc for whichever batch job is
+// currently running so a single Cancel button can abort it. Starting a
+// new job cancels any job still registered from a previous run.
+type jobCancel struct {
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}
+
+func (j *jobCancel) Start() context.Context {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.cancel != nil {
+		j.cancel()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	j.cancel = cancel
+	return ctx
+}
+
+func (j *jobCancel) Done() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.cancel = nil
+}
+
+func (j *jobCancel) Cancel() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.cancel != nil {
+		j.cancel()
+	}
+}